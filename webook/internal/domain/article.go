@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+// Author 是文章的作者信息，因为作者除了 Id 之外的字段
+// 基本上都是用来展示的，所以这里故意没有放进很多字段。
+type Author struct {
+	Id   int64
+	Name string
+}
+
+// Article 是文章的领域对象
+type Article struct {
+	Id      int64
+	Title   string
+	Content string
+	Author  Author
+	Status  ArticleStatus
+	Tags    []string
+	Slug    string
+	// Version 是乐观锁版本号，每次 PartialUpdate/Update 成功都会 +1
+	Version int64
+	Ctime   time.Time
+	Utime   time.Time
+}
+
+// ArticlePatch 是 PATCH /articles/:id 的载荷。
+// 用指针而不是值，是为了区分"调用方没传这个字段"和"调用方显式传了零值/空字符串"，
+// 只有非 nil 的字段才会出现在最终的 GORM Updates(map[string]any{...}) 里。
+type ArticlePatch struct {
+	Title   *string
+	Content *string
+	Tags    *[]string
+	Status  *ArticleStatus
+}
+
+// ArticleStatus 是文章的状态
+type ArticleStatus uint8
+
+const (
+	// ArticleStatusUnknown 为了避免零值之类的问题
+	ArticleStatusUnknown ArticleStatus = iota
+	ArticleStatusUnpublished
+	ArticleStatusPublished
+	ArticleStatusPrivate
+	// ArticleStatusDraft 草稿箱，尚未提交审核，属于后续引入的审核流程
+	ArticleStatusDraft
+	// ArticleStatusInReview 已提交审核，等待审核结果
+	ArticleStatusInReview
+	// ArticleStatusDeleted 软删除状态，不能再进行任何编辑
+	ArticleStatusDeleted
+)
+
+func (s ArticleStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+func (s ArticleStatus) Valid() bool {
+	return s >= ArticleStatusUnpublished && s <= ArticleStatusDeleted
+}
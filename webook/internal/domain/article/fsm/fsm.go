@@ -0,0 +1,134 @@
+// Package fsm 实现了文章状态机。
+//
+// articleService 以前是直接 art.Status = xxx 这样赋值，想加一个审核流程
+// 或者withdraw 之后能不能 republish 这样的规则，就得在 service 里堆 if-else。
+// 这里把“从哪个状态，经由什么事件，可以迁移到哪个状态”整理成一张表，
+// service 只管 Fire 一个事件，合法性校验和状态变更都收敛在这里。
+package fsm
+
+import (
+	"context"
+	"fmt"
+
+	"webook/internal/domain"
+)
+
+// Event 是驱动状态迁移的事件
+type Event string
+
+const (
+	// EventSaveDraft 对应 articleService.Save，保存一篇未发表的文章
+	EventSaveDraft Event = "save_draft"
+	// EventSubmit 提交审核
+	EventSubmit Event = "submit"
+	// EventApprove 审核通过
+	EventApprove Event = "approve"
+	// EventReject 审核驳回
+	EventReject Event = "reject"
+	// EventPublish 直接发表，对应 articleService.Publish
+	EventPublish Event = "publish"
+	// EventWithdraw 下架，仅自己可见，对应 articleService.Withdraw
+	EventWithdraw Event = "withdraw"
+	// EventRepublish 从仅自己可见重新发表
+	EventRepublish Event = "republish"
+	// EventDelete 软删除
+	EventDelete Event = "delete"
+)
+
+// Guard 在迁移真正发生之前校验一次，返回 error 就拒绝这次迁移
+type Guard func(ctx context.Context, art domain.Article) error
+
+// Action 是迁移本身要做的事情，默认只是改 art.Status，
+// 但是允许调用方塞一些额外的逻辑进来（例如设置发表时间）
+type Action func(ctx context.Context, art *domain.Article) error
+
+// PostHook 在迁移成功之后执行，典型用途是写审计日志。
+// PostHook 失败不会撤销已经发生的迁移，由调用方决定怎么处理这个错误。
+type PostHook func(ctx context.Context, art domain.Article, from, to domain.ArticleStatus) error
+
+// Transition 描述了一条 from -> to 的边
+type Transition struct {
+	To       domain.ArticleStatus
+	Guard    Guard
+	Action   Action
+	PostHook PostHook
+}
+
+// guardNonEmptyContent 发表之前必须要有标题和正文，不能把一篇空文章发出去——
+// 这条规则在好几个来源状态（Unknown/Unpublished 首次发表、InReview 审核通过、
+// Private 重新发表）上都适用，放进 Guard 里统一校验，比在每条边的 To 旁边
+// 各自判断一遍更不容易漏掉。
+func guardNonEmptyContent(_ context.Context, art domain.Article) error {
+	if art.Title == "" || art.Content == "" {
+		return fmt.Errorf("fsm: 标题和正文不能为空，不能发表")
+	}
+	return nil
+}
+
+// table 就是状态机本身：status -> event -> transition。
+// 新增一种工作流（比如多一轮复审）只需要往表里加一行，不需要动 service 的代码。
+var table = map[domain.ArticleStatus]map[Event]Transition{
+	domain.ArticleStatusUnknown: {
+		EventSaveDraft: {To: domain.ArticleStatusUnpublished},
+		EventPublish:   {To: domain.ArticleStatusPublished, Guard: guardNonEmptyContent},
+	},
+	domain.ArticleStatusDraft: {
+		EventSaveDraft: {To: domain.ArticleStatusUnpublished},
+		EventSubmit:    {To: domain.ArticleStatusInReview},
+	},
+	domain.ArticleStatusUnpublished: {
+		EventSaveDraft: {To: domain.ArticleStatusUnpublished},
+		EventSubmit:    {To: domain.ArticleStatusInReview},
+		EventPublish:   {To: domain.ArticleStatusPublished, Guard: guardNonEmptyContent},
+	},
+	domain.ArticleStatusInReview: {
+		EventApprove: {To: domain.ArticleStatusPublished, Guard: guardNonEmptyContent},
+		EventReject:  {To: domain.ArticleStatusUnpublished},
+	},
+	domain.ArticleStatusPublished: {
+		EventSaveDraft: {To: domain.ArticleStatusUnpublished},
+		EventWithdraw:  {To: domain.ArticleStatusPrivate},
+		EventDelete:    {To: domain.ArticleStatusDeleted},
+	},
+	domain.ArticleStatusPrivate: {
+		EventSaveDraft: {To: domain.ArticleStatusUnpublished},
+		EventRepublish: {To: domain.ArticleStatusPublished, Guard: guardNonEmptyContent},
+		EventDelete:    {To: domain.ArticleStatusDeleted},
+	},
+	// ArticleStatusDeleted 没有配置任何出边，是终态
+}
+
+// Fire 尝试把 art 从它当前的状态，按 event 迁移过去。
+// 调用方应该用 Fire 替代直接的 art.Status = xxx 赋值，
+// 这样非法迁移（比如一篇还没发表的文章被 withdraw）会在落库之前就被拒绝。
+func Fire(ctx context.Context, art *domain.Article, event Event) (from, to domain.ArticleStatus, err error) {
+	from = art.Status
+	transitions, ok := table[from]
+	if !ok {
+		return from, from, fmt.Errorf("fsm: 状态 %d 没有配置任何迁移", from)
+	}
+	trans, ok := transitions[event]
+	if !ok {
+		return from, from, fmt.Errorf("fsm: 状态 %d 不支持事件 %q", from, event)
+	}
+	if trans.Guard != nil {
+		if gerr := trans.Guard(ctx, *art); gerr != nil {
+			return from, from, gerr
+		}
+	}
+	art.Status = trans.To
+	if trans.Action != nil {
+		if aerr := trans.Action(ctx, art); aerr != nil {
+			// Action 失败，回滚状态，这次迁移当作没发生过
+			art.Status = from
+			return from, from, aerr
+		}
+	}
+	to = trans.To
+	if trans.PostHook != nil {
+		if herr := trans.PostHook(ctx, *art, from, to); herr != nil {
+			return from, to, herr
+		}
+	}
+	return from, to, nil
+}
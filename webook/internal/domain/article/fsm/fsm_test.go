@@ -0,0 +1,52 @@
+package fsm
+
+import (
+	"context"
+	"testing"
+
+	"webook/internal/domain"
+)
+
+func TestFire_LegalTransition(t *testing.T) {
+	art := domain.Article{
+		Title:   "标题",
+		Content: "正文",
+		Status:  domain.ArticleStatusUnpublished,
+	}
+	from, to, err := Fire(context.Background(), &art, EventPublish)
+	if err != nil {
+		t.Fatalf("expected legal transition to succeed, got error: %v", err)
+	}
+	if from != domain.ArticleStatusUnpublished || to != domain.ArticleStatusPublished {
+		t.Fatalf("unexpected from/to: %v -> %v", from, to)
+	}
+	if art.Status != domain.ArticleStatusPublished {
+		t.Fatalf("art.Status not updated, got %v", art.Status)
+	}
+}
+
+func TestFire_IllegalTransition(t *testing.T) {
+	// Unpublished 没有配置 EventWithdraw 这条边：一篇还没发表的文章不能下架。
+	art := domain.Article{Status: domain.ArticleStatusUnpublished}
+	from, to, err := Fire(context.Background(), &art, EventWithdraw)
+	if err == nil {
+		t.Fatal("expected illegal transition to fail")
+	}
+	if from != domain.ArticleStatusUnpublished || to != domain.ArticleStatusUnpublished {
+		t.Fatalf("illegal transition should not change from/to, got %v -> %v", from, to)
+	}
+	if art.Status != domain.ArticleStatusUnpublished {
+		t.Fatalf("art.Status should be unchanged after illegal transition, got %v", art.Status)
+	}
+}
+
+func TestFire_GuardRejectsEmptyContent(t *testing.T) {
+	art := domain.Article{Status: domain.ArticleStatusUnpublished}
+	_, _, err := Fire(context.Background(), &art, EventPublish)
+	if err == nil {
+		t.Fatal("expected guardNonEmptyContent to reject publishing an empty article")
+	}
+	if art.Status != domain.ArticleStatusUnpublished {
+		t.Fatalf("art.Status should be rolled back when guard rejects, got %v", art.Status)
+	}
+}
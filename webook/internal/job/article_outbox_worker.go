@@ -0,0 +1,108 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"time"
+
+	"webook/internal/repository/article"
+	daoarticle "webook/internal/repository/dao/article"
+	"webook/pkg/logger"
+)
+
+// ArticleOutboxWorker 轮询 article_publish_outbox，把制作库里新写入/更新的文章
+// 同步到线上库。换成监听 Kafka/NSQ 也是可以的，轮询只是最简单的起步版本。
+type ArticleOutboxWorker struct {
+	outboxDAO article.ArticleOutboxDAO
+	readerDAO daoarticle.ArticleReaderDAO
+	l         logger.LoggerV1
+
+	// batchSize 每一轮取多少条 pending 记录
+	batchSize int
+	// maxAttempts 超过这个重试次数就标记为死信，不再自动重试，等人工 Republish
+	maxAttempts int
+}
+
+func NewArticleOutboxWorker(outboxDAO article.ArticleOutboxDAO,
+	readerDAO daoarticle.ArticleReaderDAO, l logger.LoggerV1) *ArticleOutboxWorker {
+	return &ArticleOutboxWorker{
+		outboxDAO:   outboxDAO,
+		readerDAO:   readerDAO,
+		l:           l,
+		batchSize:   50,
+		maxAttempts: 10,
+	}
+}
+
+// Start 按照 interval 一直轮询，直到 ctx 被取消
+func (w *ArticleOutboxWorker) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.syncOnce(ctx); err != nil {
+				w.l.Error("同步 article outbox 失败", logger.Error(err))
+			}
+		}
+	}
+}
+
+func (w *ArticleOutboxWorker) syncOnce(ctx context.Context) error {
+	rows, err := w.outboxDAO.FindPending(ctx, w.batchSize)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		w.syncOne(ctx, row)
+	}
+	return nil
+}
+
+func (w *ArticleOutboxWorker) syncOne(ctx context.Context, row article.ArticleOutbox) {
+	var art daoarticle.PublishedArticle
+	if err := json.Unmarshal([]byte(row.PayloadJSON), &art); err != nil {
+		// payload 本身就是坏的，重试也不会变好，直接当毒丸处理
+		w.l.Error("outbox payload 反序列化失败，标记为死信",
+			logger.Field{Key: "outbox_id", Value: row.Id}, logger.Error(err))
+		if err := w.outboxDAO.MarkDead(ctx, row.Id); err != nil {
+			w.l.Error("标记 outbox 死信失败",
+				logger.Field{Key: "outbox_id", Value: row.Id}, logger.Error(err))
+		}
+		return
+	}
+	err := w.readerDAO.Upsert(ctx, daoarticle.Article(art))
+	if err == nil {
+		if err := w.outboxDAO.MarkDone(ctx, row.Id); err != nil {
+			w.l.Error("标记 outbox 完成失败",
+				logger.Field{Key: "outbox_id", Value: row.Id}, logger.Error(err))
+		}
+		return
+	}
+	attempts := row.Attempts + 1
+	w.l.Error("同步 outbox 到线上库失败",
+		logger.Field{Key: "outbox_id", Value: row.Id},
+		logger.Field{Key: "attempts", Value: attempts},
+		logger.Error(err))
+	if attempts >= w.maxAttempts {
+		if err := w.outboxDAO.MarkDead(ctx, row.Id); err != nil {
+			w.l.Error("标记 outbox 死信失败",
+				logger.Field{Key: "outbox_id", Value: row.Id}, logger.Error(err))
+		}
+		return
+	}
+	if err := w.outboxDAO.MarkFailed(ctx, row.Id, attempts, time.Now().Add(nextBackoff(attempts))); err != nil {
+		w.l.Error("记录 outbox 重试状态失败",
+			logger.Field{Key: "outbox_id", Value: row.Id}, logger.Error(err))
+	}
+}
+
+// nextBackoff 按尝试次数算出下一次重试前要等多久：指数退避，2^attempts 秒，
+// 封顶 1 小时，免得 attempts 一直涨上去算出一个大到没意义的延迟。
+// 拆成一个独立的纯函数方便单测，不用为了测退避曲线去搭一整个 DAO。
+func nextBackoff(attempts int) time.Duration {
+	return time.Duration(math.Min(float64(time.Hour), float64(time.Second)*math.Pow(2, float64(attempts))))
+}
@@ -0,0 +1,33 @@
+package job
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextBackoff_DoublesUntilCapped(t *testing.T) {
+	cases := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: time.Second},
+		{attempts: 1, want: 2 * time.Second},
+		{attempts: 2, want: 4 * time.Second},
+		{attempts: 3, want: 8 * time.Second},
+	}
+	for _, c := range cases {
+		if got := nextBackoff(c.attempts); got != c.want {
+			t.Fatalf("nextBackoff(%d) = %v, want %v", c.attempts, got, c.want)
+		}
+	}
+}
+
+func TestNextBackoff_CappedAtOneHour(t *testing.T) {
+	// 2^12 秒（4096 秒）已经超过 1 小时（3600 秒），退避曲线到这里必须封顶，不能无限涨上去。
+	if got := nextBackoff(12); got != time.Hour {
+		t.Fatalf("nextBackoff(12) = %v, want capped at %v", got, time.Hour)
+	}
+	if got := nextBackoff(30); got != time.Hour {
+		t.Fatalf("nextBackoff(30) = %v, want capped at %v", got, time.Hour)
+	}
+}
@@ -2,19 +2,53 @@ package article
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	"gorm.io/gorm"
 	"webook/internal/domain"
+	"webook/internal/domain/article/fsm"
 	"webook/internal/repository/dao/article"
+	"webook/pkg/logger"
 )
 
+// ErrVersionConflict 在 PartialUpdate 传入的 version 和库里当前的 version 对不上时返回，
+// 说明这期间有别的请求已经改过这篇文章了。
+var ErrVersionConflict = errors.New("article: version conflict")
+
+// ErrArticleNotFound 在按 (id, uid) 找不到文章时返回——不管是这篇文章压根不存在，
+// 还是存在但不属于这个 uid，统一返回同一个错误，不把"文章存在，只是不是你的"
+// 这种归属信息泄露给无权限的调用方。
+var ErrArticleNotFound = errors.New("article: 文章不存在")
+
 type ArticleRepository interface {
 	Create(ctx context.Context, art domain.Article) (int64, error)
 	Update(ctx context.Context, art domain.Article) error
 
 	// Sync 本身要求先保存到制作库，再同步到线上库
 	Sync(ctx context.Context, art domain.Article) (int64, error)
-	// SyncStatus 仅仅同步状态
+	// SyncStatus 仅仅同步状态，内部通过 fsm 校验这次迁移是否合法
 	SyncStatus(ctx context.Context, uid, id int64, status domain.ArticleStatus) error
+	// LogTransition 记录一次状态机迁移，作为审计日志落到 article_status_log 表
+	LogTransition(ctx context.Context, uid, id int64, from, to domain.ArticleStatus, event fsm.Event, reason string) error
+	// Republish 把一条卡在 outbox 里的死信记录重新投递，供 ArticleOutboxWorker 重试
+	Republish(ctx context.Context, artId int64) error
+	// Search 用 Query 承载的动态条件查询文章，返回结果和命中总数
+	Search(ctx context.Context, q Query) ([]domain.Article, int64, error)
+	// GetPublishedById 读一篇已发表的文章，配置了 publishedCache 的话会走读穿缓存
+	GetPublishedById(ctx context.Context, id int64) (domain.Article, error)
+	// GetById 读一篇文章当前的持久化状态，不区分发表与否，也不做归属校验——
+	// 调用方（比如 Save/Publish 在触发 fsm.Fire 之前）自己决定要不要做权限检查。
+	GetById(ctx context.Context, id int64) (domain.Article, error)
+	// PartialUpdate 只更新 patch 里非 nil 的字段，WHERE 里同时校验 author_id 和
+	// expectedVersion，任何一个对不上都返回 ErrVersionConflict。
+	// patch.Status 不是简单拼列，而是先经过 fsm.Fire 校验、成功才记 LogTransition，
+	// 和 SyncStatus 走的是同一条路，不允许绕过状态机直接改 status。
+	// 返回值是更新成功之后的新 version。
+	PartialUpdate(ctx context.Context, uid, id, expectedVersion int64, patch domain.ArticlePatch) (int64, error)
 }
 
 type CachedArticleRepository struct {
@@ -27,6 +61,13 @@ type CachedArticleRepository struct {
 
 	// SyncV2 用
 	db *gorm.DB
+
+	// publishedCache 是可选的多级索引内存缓存，没配置的话 GetPublishedById 就直接走 dao
+	publishedCache *CachedPublishedArticleStore
+
+	// l 只用来记录"状态已经落库，但审计日志没写成功"这种不应该让调用方重试的错误，
+	// 不配置就是静默跳过，不影响主流程
+	l logger.LoggerV1
 }
 
 func NewArticleRepository(dao article.ArticleDAO) ArticleRepository {
@@ -43,14 +84,377 @@ func NewArticleRepositoryV1(authorDAO article.ArticleAuthorDAO,
 	}
 }
 
+// NewArticleRepositoryV2 和 NewArticleRepository 的区别是 Sync 走事务性 outbox：
+// 写制作库和写 outbox 记录在同一个事务里，真正同步到线上库交给
+// job.ArticleOutboxWorker 异步完成，因此作者库和线上库可以不是同一个 DB。
+func NewArticleRepositoryV2(dao article.ArticleDAO, db *gorm.DB, l logger.LoggerV1) ArticleRepository {
+	return &CachedArticleRepository{
+		dao: dao,
+		db:  db,
+		l:   l,
+	}
+}
+
+// NewArticleRepositoryV3 在 V2 的基础上挂上一个多级索引内存缓存：
+// GetPublishedById 会优先读缓存，Sync/SyncStatus 成功之后会让对应文章的缓存失效。
+func NewArticleRepositoryV3(dao article.ArticleDAO, db *gorm.DB, cache *CachedPublishedArticleStore, l logger.LoggerV1) ArticleRepository {
+	return &CachedArticleRepository{
+		dao:            dao,
+		db:             db,
+		publishedCache: cache,
+		l:              l,
+	}
+}
+
 func (repo *CachedArticleRepository) SyncStatus(ctx context.Context,
 	uid, id int64, status domain.ArticleStatus) error {
-	return repo.dao.SyncStatus(ctx, uid, id, status.ToUint8())
+	// 先查出当前状态，交给状态机判断这次迁移合不合法，
+	// 而不是不管三七二十一直接覆盖过去。
+	entity, err := repo.dao.GetById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrArticleNotFound
+		}
+		return err
+	}
+	// 归属校验必须在 eventForTargetStatus/fsm.Fire 之前做：这两者的报错会区分
+	// "不在审核中"/"已经发表"/"已经下架"这些具体的状态类别，如果不限定 author_id
+	// 就把文章的当前状态喂给它们，无权限的调用方就能拿别人的文章 id 反复试
+	// Withdraw/Submit/Approve/Reject，从报错里反推出这篇文章到底处于哪个状态——
+	// 和 PartialUpdate 里那次同样的教训（见 ErrArticleNotFound 的注释）。
+	if entity.AuthorId != uid {
+		return ErrArticleNotFound
+	}
+	art := domain.Article{Id: id, Status: domain.ArticleStatus(entity.Status)}
+	event, err := eventForTargetStatus(art.Status, status)
+	if err != nil {
+		return err
+	}
+	from, to, err := fsm.Fire(ctx, &art, event)
+	if err != nil {
+		return err
+	}
+	if err := repo.dao.SyncStatus(ctx, uid, id, to.ToUint8()); err != nil {
+		return err
+	}
+	if repo.publishedCache != nil {
+		repo.publishedCache.Invalidate(id)
+	}
+	// 状态已经落库了，审计日志写失败不能再让调用方以为这次操作没有生效——
+	// 那样重试多半会被状态机拒绝，因为 art.Status 其实已经变了（比如 Withdraw 之后
+	// 文章已经是 Private，Private 上没有 withdraw 这条出边），调用方会看到一个
+	// 莫名其妙、永远修不好的错误。
+	if err := repo.LogTransition(ctx, uid, id, from, to, event, ReasonForEvent(event)); err != nil {
+		repo.logAuditFailure(uid, id, from, to, event, err)
+	}
+	return nil
+}
+
+func (repo *CachedArticleRepository) logAuditFailure(uid, id int64, from, to domain.ArticleStatus, event fsm.Event, err error) {
+	if repo.l == nil {
+		return
+	}
+	repo.l.Error("记录文章状态流转审计日志失败",
+		logger.Field{Key: "uid", Value: uid},
+		logger.Field{Key: "art_id", Value: id},
+		logger.Field{Key: "from", Value: from},
+		logger.Field{Key: "to", Value: to},
+		logger.Field{Key: "event", Value: string(event)},
+		logger.Error(err))
+}
+
+// GetById 读一篇文章当前的持久化状态，不区分发表与否，也不做归属校验。
+// Save/Publish 在触发 fsm.Fire 之前用它查出真实的 art.Status 和归属，
+// 不能信任调用方传进来的 art.Status（大概率是零值）。
+func (repo *CachedArticleRepository) GetById(ctx context.Context, id int64) (domain.Article, error) {
+	entity, err := repo.dao.GetById(ctx, id)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	return repo.toDomain(entity), nil
+}
+
+// eventForTargetStatus 把"当前状态 -> 目标状态"翻译成状态机事件。
+// 调用方（SyncStatus/PartialUpdate）习惯了直接说"我要把文章改成什么状态"，
+// 但状态机只认事件，这里做一次薄薄的转换。
+//
+// 光看目标状态是不够的，来源状态同样参与判断：
+//   - 目标是 Published 的时候，来源是 Private（下架后重新发表）走 EventRepublish，
+//     来源是 InReview（审核通过）走 EventApprove，其它情况（比如一篇 Unpublished
+//     的草稿第一次发表）走 EventPublish——三者在 fsm 的 table 里是完全不同的边。
+//   - 目标是 Unpublished 的时候，来源是 InReview（审核驳回）走 EventReject，
+//     其它情况走 EventSaveDraft。
+//   - 目标是 InReview，也就是提交审核，走 EventSubmit。
+func eventForTargetStatus(from, to domain.ArticleStatus) (fsm.Event, error) {
+	switch to {
+	case domain.ArticleStatusPrivate:
+		return fsm.EventWithdraw, nil
+	case domain.ArticleStatusPublished:
+		switch from {
+		case domain.ArticleStatusPrivate:
+			return fsm.EventRepublish, nil
+		case domain.ArticleStatusInReview:
+			return fsm.EventApprove, nil
+		default:
+			return fsm.EventPublish, nil
+		}
+	case domain.ArticleStatusUnpublished:
+		if from == domain.ArticleStatusInReview {
+			return fsm.EventReject, nil
+		}
+		return fsm.EventSaveDraft, nil
+	case domain.ArticleStatusInReview:
+		return fsm.EventSubmit, nil
+	case domain.ArticleStatusDeleted:
+		return fsm.EventDelete, nil
+	default:
+		return "", fmt.Errorf("article: 不支持直接同步到状态 %d", to)
+	}
+}
+
+// LogTransition 记录一次状态机迁移，用作审计日志
+func (repo *CachedArticleRepository) LogTransition(ctx context.Context,
+	uid, id int64, from, to domain.ArticleStatus, event fsm.Event, reason string) error {
+	return repo.dao.LogTransition(ctx, uid, id, from.ToUint8(), to.ToUint8(), string(event), reason)
+}
+
+// ReasonForEvent 给审计日志一个人类可读的 reason，而不是每次调用都传个空字符串——
+// 不然"真实的审计日志"这个卖点就只是记了 from/to/event，却没说清楚这次操作具体是什么。
+func ReasonForEvent(event fsm.Event) string {
+	switch event {
+	case fsm.EventSaveDraft:
+		return "保存草稿"
+	case fsm.EventSubmit:
+		return "提交审核"
+	case fsm.EventApprove:
+		return "审核通过"
+	case fsm.EventReject:
+		return "审核驳回"
+	case fsm.EventPublish:
+		return "发表"
+	case fsm.EventWithdraw:
+		return "下架"
+	case fsm.EventRepublish:
+		return "重新发表"
+	case fsm.EventDelete:
+		return "删除"
+	default:
+		return ""
+	}
 }
 
 func (repo *CachedArticleRepository) Sync(ctx context.Context,
 	art domain.Article) (int64, error) {
-	return repo.dao.Sync(ctx, repo.toEntity(art))
+	// 没有配置 db 就是老的单库写法，保持兼容
+	if repo.db == nil {
+		return repo.dao.Sync(ctx, repo.toEntity(art))
+	}
+	// 事务性 outbox：写制作库和写 outbox 记录在同一个事务里提交，
+	// 要么都成功，要么都不成功，不会出现 PublishV1 那种"重试三次还是可能丢更新"的窗口。
+	// 真正同步到线上库的动作由 ArticleOutboxWorker 异步去做。
+	tx := repo.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+	defer tx.Rollback()
+	authorDAO := article.NewGORMArticleDAO(tx)
+	artn := repo.toEntity(art)
+	var (
+		id  = art.Id
+		err error
+	)
+	if id == 0 {
+		id, err = authorDAO.Insert(ctx, artn)
+	} else {
+		err = authorDAO.UpdateById(ctx, artn)
+	}
+	if err != nil {
+		return 0, err
+	}
+	artn.Id = id
+	payload, err := json.Marshal(article.PublishedArticle(artn))
+	if err != nil {
+		return 0, err
+	}
+	err = NewGORMArticleOutboxDAO(tx).Insert(ctx, ArticleOutbox{
+		ArtId:       id,
+		PayloadJSON: string(payload),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+	if repo.publishedCache != nil {
+		repo.publishedCache.Invalidate(id)
+	}
+	return id, nil
+}
+
+// Republish 把一条死信 outbox 记录重新拉回 pending，等待 worker 下一轮重试
+func (repo *CachedArticleRepository) Republish(ctx context.Context, artId int64) error {
+	if repo.db == nil {
+		return fmt.Errorf("article: 当前 repository 没有配置 db，不支持 Republish")
+	}
+	return NewGORMArticleOutboxDAO(repo.db).Resurrect(ctx, artId)
+}
+
+func (repo *CachedArticleRepository) Search(ctx context.Context, q Query) ([]domain.Article, int64, error) {
+	if repo.db == nil {
+		return nil, 0, fmt.Errorf("article: 当前 repository 没有配置 db，不支持 Search")
+	}
+	base := q.apply(repo.db.WithContext(ctx).Model(&article.Article{}))
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entities []article.Article
+	err := base.Order(q.orderBy + " " + q.orderDir).
+		Offset(q.offset()).Limit(q.size).
+		Find(&entities).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	res := make([]domain.Article, 0, len(entities))
+	for _, e := range entities {
+		res = append(res, repo.toDomain(e))
+	}
+	return res, total, nil
+}
+
+// PartialUpdate 生成一个只包含 patch 里非 nil 字段的 GORM Updates(map[string]any{...})，
+// WHERE 用 "id = ? AND author_id = ? AND version = ?"：author_id 保证调用方只能改
+// 自己的文章，version 是乐观锁，两者任何一个对不上，RowsAffected == 0，返回
+// ErrVersionConflict 交给调用方决定要不要重试（不细分"不是你的文章"和"版本过期"，
+// 避免把文章归属信息泄露给无权限的调用方）。
+//
+// patch.Status 不会直接拼进 cols：状态变更必须经过 fsm.Fire 校验、成功之后记一条
+// LogTransition，和 SyncStatus 走的是同一条路，否则 PATCH 就是绕过整个状态机的后门。
+func (repo *CachedArticleRepository) PartialUpdate(ctx context.Context,
+	uid, id, expectedVersion int64, patch domain.ArticlePatch) (int64, error) {
+	if repo.db == nil {
+		return 0, fmt.Errorf("article: 当前 repository 没有配置 db，不支持 PartialUpdate")
+	}
+
+	var (
+		from, to domain.ArticleStatus
+		event    fsm.Event
+		hasEvent bool
+	)
+	if patch.Status != nil {
+		// 这一步必须按 uid 过滤：如果查当前状态不限定 author_id，一个无权限的调用方
+		// 就能从 fsm.Fire 的报错里反推出"这篇文章存在，只是这次迁移不合法"，
+		// 跟上面说的"不区分不是你的文章/版本过期"自相矛盾，等于还是泄露了归属信息。
+		var entity article.Article
+		err := repo.db.WithContext(ctx).Model(&article.Article{}).
+			Where("id = ? AND author_id = ?", id, uid).
+			First(&entity).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return 0, ErrVersionConflict
+			}
+			return 0, err
+		}
+		art := domain.Article{Id: id, Status: domain.ArticleStatus(entity.Status)}
+		event, err = eventForTargetStatus(art.Status, *patch.Status)
+		if err != nil {
+			return 0, err
+		}
+		from, to, err = fsm.Fire(ctx, &art, event)
+		if err != nil {
+			return 0, err
+		}
+		hasEvent = true
+	}
+
+	cols := map[string]any{}
+	if patch.Title != nil {
+		cols["title"] = *patch.Title
+	}
+	if patch.Content != nil {
+		cols["content"] = *patch.Content
+	}
+	if patch.Tags != nil {
+		cols["tags"] = strings.Join(*patch.Tags, ",")
+	}
+	if hasEvent {
+		cols["status"] = to.ToUint8()
+	}
+
+	if len(cols) == 0 {
+		// patch 里一个字段都没给，没有列可以 Updates，但仍然要确认这篇文章
+		// 存在、属于 uid、并且 version 对得上，不能什么都不查就告诉调用方"成功了"。
+		var count int64
+		err := repo.db.WithContext(ctx).Model(&article.Article{}).
+			Where("id = ? AND author_id = ? AND version = ?", id, uid, expectedVersion).
+			Count(&count).Error
+		if err != nil {
+			return 0, err
+		}
+		if count == 0 {
+			return 0, ErrVersionConflict
+		}
+		return expectedVersion, nil
+	}
+	newVersion := expectedVersion + 1
+	cols["version"] = newVersion
+	cols["utime"] = time.Now().UnixMilli()
+
+	res := repo.db.WithContext(ctx).Model(&article.Article{}).
+		Where("id = ? AND author_id = ? AND version = ?", id, uid, expectedVersion).
+		Updates(cols)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return 0, ErrVersionConflict
+	}
+	if repo.publishedCache != nil {
+		repo.publishedCache.Invalidate(id)
+	}
+	if hasEvent {
+		if err := repo.LogTransition(ctx, uid, id, from, to, event, ReasonForEvent(event)); err != nil {
+			repo.logAuditFailure(uid, id, from, to, event, err)
+		}
+	}
+	return newVersion, nil
+}
+
+func (repo *CachedArticleRepository) GetPublishedById(ctx context.Context, id int64) (domain.Article, error) {
+	if repo.publishedCache != nil {
+		if art, ok := repo.publishedCache.GetById(id); ok {
+			return art, nil
+		}
+	}
+	entity, err := repo.dao.GetPublishedById(ctx, id)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	art := repo.toDomain(entity)
+	if repo.publishedCache != nil {
+		repo.publishedCache.Put(art)
+	}
+	return art, nil
+}
+
+func (repo *CachedArticleRepository) toDomain(e article.Article) domain.Article {
+	var tags []string
+	if e.Tags != "" {
+		tags = strings.Split(e.Tags, ",")
+	}
+	return domain.Article{
+		Id:      e.Id,
+		Title:   e.Title,
+		Content: e.Content,
+		Author:  domain.Author{Id: e.AuthorId},
+		Status:  domain.ArticleStatus(e.Status),
+		Tags:    tags,
+		Slug:    e.Slug,
+		Version: e.Version,
+	}
 }
 
 func (repo *CachedArticleRepository) SyncV2(ctx context.Context,
@@ -134,6 +538,9 @@ func (repo *CachedArticleRepository) toEntity(art domain.Article) article.Articl
 		// 这一步，就是将领域状态转化为存储状态。
 		// 这里我们就是直接转换，
 		// 有些情况下，这里可能是借助一个 map 来转
-		Status: uint8(art.Status),
+		Status:  uint8(art.Status),
+		Tags:    strings.Join(art.Tags, ","),
+		Slug:    art.Slug,
+		Version: art.Version,
 	}
 }
@@ -0,0 +1,90 @@
+package article
+
+import (
+	"testing"
+
+	"webook/internal/domain"
+	"webook/internal/domain/article/fsm"
+)
+
+func TestEventForTargetStatus(t *testing.T) {
+	cases := []struct {
+		name    string
+		from    domain.ArticleStatus
+		to      domain.ArticleStatus
+		want    fsm.Event
+		wantErr bool
+	}{
+		{
+			name: "unpublished draft published for the first time uses EventPublish",
+			from: domain.ArticleStatusUnpublished,
+			to:   domain.ArticleStatusPublished,
+			want: fsm.EventPublish,
+		},
+		{
+			name: "withdrawn article published again uses EventRepublish, not EventPublish",
+			from: domain.ArticleStatusPrivate,
+			to:   domain.ArticleStatusPublished,
+			want: fsm.EventRepublish,
+		},
+		{
+			name: "in-review article approved uses EventApprove, not EventPublish",
+			from: domain.ArticleStatusInReview,
+			to:   domain.ArticleStatusPublished,
+			want: fsm.EventApprove,
+		},
+		{
+			name: "in-review article rejected uses EventReject, not EventSaveDraft",
+			from: domain.ArticleStatusInReview,
+			to:   domain.ArticleStatusUnpublished,
+			want: fsm.EventReject,
+		},
+		{
+			name: "saving a draft uses EventSaveDraft",
+			from: domain.ArticleStatusPublished,
+			to:   domain.ArticleStatusUnpublished,
+			want: fsm.EventSaveDraft,
+		},
+		{
+			name: "submitting for review uses EventSubmit",
+			from: domain.ArticleStatusUnpublished,
+			to:   domain.ArticleStatusInReview,
+			want: fsm.EventSubmit,
+		},
+		{
+			name: "withdrawing a published article uses EventWithdraw",
+			from: domain.ArticleStatusPublished,
+			to:   domain.ArticleStatusPrivate,
+			want: fsm.EventWithdraw,
+		},
+		{
+			name: "deleting an article uses EventDelete",
+			from: domain.ArticleStatusPublished,
+			to:   domain.ArticleStatusDeleted,
+			want: fsm.EventDelete,
+		},
+		{
+			name:    "unsupported target status is rejected",
+			from:    domain.ArticleStatusUnpublished,
+			to:      domain.ArticleStatusUnknown,
+			wantErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := eventForTargetStatus(c.from, c.to)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error for %v -> %v, got event %q", c.from, c.to, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error for %v -> %v: %v", c.from, c.to, err)
+			}
+			if got != c.want {
+				t.Fatalf("eventForTargetStatus(%v, %v) = %q, want %q", c.from, c.to, got, c.want)
+			}
+		})
+	}
+}
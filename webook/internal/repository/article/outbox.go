@@ -0,0 +1,112 @@
+package article
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OutboxStatus 是 article_publish_outbox 记录的状态
+type OutboxStatus uint8
+
+const (
+	OutboxStatusPending OutboxStatus = iota
+	OutboxStatusDone
+	// OutboxStatusDead 重试次数耗尽，需要人工介入（参见 Republish）
+	OutboxStatusDead
+)
+
+// ArticleOutbox 对应 article_publish_outbox 表。
+//
+// 它存在的意义是把"写作者库"和"同步到线上库"这两件事解耦：
+// 只要写作者库和插入这一行在同一个事务里，就保证了制作库一定不会丢更新，
+// 而真正把数据搬到线上库的动作，交给 ArticleOutboxWorker 异步重试着去做，
+// 两边的库甚至可以不是同一个 GORM DB。
+type ArticleOutbox struct {
+	Id          int64        `gorm:"primaryKey,autoIncrement"`
+	ArtId       int64        `gorm:"index"`
+	PayloadJSON string       `gorm:"type:text"`
+	Status      OutboxStatus `gorm:"index:idx_status_retry"`
+	Attempts    int
+	// NextRetryAt 下一次可以被 worker 捞出来重试的时间，用于退避，毫秒数
+	NextRetryAt int64 `gorm:"index:idx_status_retry"`
+	Ctime       int64
+	Utime       int64
+}
+
+func (ArticleOutbox) TableName() string {
+	return "article_publish_outbox"
+}
+
+type ArticleOutboxDAO interface {
+	Insert(ctx context.Context, ob ArticleOutbox) error
+	FindPending(ctx context.Context, limit int) ([]ArticleOutbox, error)
+	MarkDone(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, attempts int, nextRetryAt time.Time) error
+	MarkDead(ctx context.Context, id int64) error
+	// Resurrect 把一条死信记录重新拉回 pending，供 Republish 使用
+	Resurrect(ctx context.Context, artId int64) error
+}
+
+type GORMArticleOutboxDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMArticleOutboxDAO(db *gorm.DB) ArticleOutboxDAO {
+	return &GORMArticleOutboxDAO{db: db}
+}
+
+func (dao *GORMArticleOutboxDAO) Insert(ctx context.Context, ob ArticleOutbox) error {
+	now := time.Now().UnixMilli()
+	ob.Status = OutboxStatusPending
+	ob.Ctime, ob.Utime = now, now
+	return dao.db.WithContext(ctx).Create(&ob).Error
+}
+
+func (dao *GORMArticleOutboxDAO) FindPending(ctx context.Context, limit int) ([]ArticleOutbox, error) {
+	var res []ArticleOutbox
+	err := dao.db.WithContext(ctx).
+		Where("status = ? AND next_retry_at <= ?", OutboxStatusPending, time.Now().UnixMilli()).
+		Order("id ASC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleOutboxDAO) MarkDone(ctx context.Context, id int64) error {
+	return dao.db.WithContext(ctx).Model(&ArticleOutbox{}).Where("id = ?", id).
+		Updates(map[string]any{
+			"status": OutboxStatusDone,
+			"utime":  time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *GORMArticleOutboxDAO) MarkFailed(ctx context.Context, id int64, attempts int, nextRetryAt time.Time) error {
+	return dao.db.WithContext(ctx).Model(&ArticleOutbox{}).Where("id = ?", id).
+		Updates(map[string]any{
+			"attempts":      attempts,
+			"next_retry_at": nextRetryAt.UnixMilli(),
+			"utime":         time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *GORMArticleOutboxDAO) MarkDead(ctx context.Context, id int64) error {
+	return dao.db.WithContext(ctx).Model(&ArticleOutbox{}).Where("id = ?", id).
+		Updates(map[string]any{
+			"status": OutboxStatusDead,
+			"utime":  time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *GORMArticleOutboxDAO) Resurrect(ctx context.Context, artId int64) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Model(&ArticleOutbox{}).
+		Where("art_id = ? AND status = ?", artId, OutboxStatusDead).
+		Updates(map[string]any{
+			"status":        OutboxStatusPending,
+			"attempts":      0,
+			"next_retry_at": now,
+			"utime":         now,
+		}).Error
+}
@@ -0,0 +1,256 @@
+package article
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"webook/internal/domain"
+	"webook/internal/repository/dao/article"
+)
+
+// EventType 描述多级索引缓存里发生的变更类型
+type EventType uint8
+
+const (
+	EventAdd EventType = iota
+	EventUpdate
+	EventDelete
+)
+
+// Event 是 Watch 的订阅者收到的通知，例如搜索索引构建器可以订阅它来增量更新索引，
+// 而不用对线上库做轮询。
+type Event struct {
+	Type    EventType
+	Article domain.Article
+}
+
+// IndexEntry 是缓存里的一条记录，id/author_id/slug 三个索引都指向同一个
+// IndexEntry，失效的时候从任意一个索引命中都能把三份索引一起清掉。
+type IndexEntry struct {
+	art domain.Article
+}
+
+// CacheMetrics 是多级索引缓存的命中率统计
+type CacheMetrics struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+}
+
+// CachedPublishedArticleStore 是已发表文章的多级索引内存缓存：
+// 按 id、author_id、slug 三个维度都能直接查到同一篇文章，做法参考了
+// k8s informer、APISIX route store 这类控制器里常见的多级索引缓存。
+//
+// 除了 Resync（启动时通过 readerDAO 预热）之外，它不负责从线上库加载数据，
+// 其余时候只是一个纯内存的索引层，数据由 CachedArticleRepository.GetPublishedById（读穿）写进来。
+type CachedPublishedArticleStore struct {
+	mu sync.RWMutex
+
+	byId     map[int64]*IndexEntry
+	byAuthor map[int64]map[int64]*IndexEntry // authorId -> artId -> entry
+	bySlug   map[string]*IndexEntry
+
+	// lruOrder 记录访问顺序，越靠后越新，超出 capacity 就淘汰最前面的
+	lruOrder []int64
+	capacity int
+
+	readerDAO article.ArticleReaderDAO
+
+	watchersMu sync.Mutex
+	watchers   []chan Event
+
+	metrics CacheMetrics
+}
+
+// NewCachedPublishedArticleStore 创建一个多级索引缓存，capacity <= 0 时使用一个保守的默认值
+func NewCachedPublishedArticleStore(readerDAO article.ArticleReaderDAO, capacity int) *CachedPublishedArticleStore {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	return &CachedPublishedArticleStore{
+		byId:      make(map[int64]*IndexEntry),
+		byAuthor:  make(map[int64]map[int64]*IndexEntry),
+		bySlug:    make(map[string]*IndexEntry),
+		capacity:  capacity,
+		readerDAO: readerDAO,
+	}
+}
+
+func (s *CachedPublishedArticleStore) GetById(id int64) (domain.Article, bool) {
+	s.mu.RLock()
+	entry, ok := s.byId[id]
+	s.mu.RUnlock()
+	if !ok {
+		atomic.AddInt64(&s.metrics.Misses, 1)
+		return domain.Article{}, false
+	}
+	atomic.AddInt64(&s.metrics.Hits, 1)
+	s.touch(id)
+	return entry.art, true
+}
+
+func (s *CachedPublishedArticleStore) GetBySlug(slug string) (domain.Article, bool) {
+	s.mu.RLock()
+	entry, ok := s.bySlug[slug]
+	s.mu.RUnlock()
+	if !ok {
+		atomic.AddInt64(&s.metrics.Misses, 1)
+		return domain.Article{}, false
+	}
+	atomic.AddInt64(&s.metrics.Hits, 1)
+	s.touch(entry.art.Id)
+	return entry.art, true
+}
+
+func (s *CachedPublishedArticleStore) GetByAuthor(authorId int64) []domain.Article {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entries := s.byAuthor[authorId]
+	res := make([]domain.Article, 0, len(entries))
+	for _, e := range entries {
+		res = append(res, e.art)
+	}
+	return res
+}
+
+// Put 写入/更新一篇文章，三个索引一起原子地替换掉
+func (s *CachedPublishedArticleStore) Put(art domain.Article) {
+	s.mu.Lock()
+	_, existed := s.invalidateLocked(art.Id)
+	entry := &IndexEntry{art: art}
+	s.byId[art.Id] = entry
+	if s.byAuthor[art.Author.Id] == nil {
+		s.byAuthor[art.Author.Id] = make(map[int64]*IndexEntry)
+	}
+	s.byAuthor[art.Author.Id][art.Id] = entry
+	if art.Slug != "" {
+		s.bySlug[art.Slug] = entry
+	}
+	s.lruOrder = append(s.lruOrder, art.Id)
+	s.evictIfNeededLocked()
+	s.mu.Unlock()
+
+	evtType := EventAdd
+	if existed {
+		evtType = EventUpdate
+	}
+	s.notify(Event{Type: evtType, Article: art})
+}
+
+// Invalidate 把 id 对应的三个索引都原子地清掉，Sync/SyncStatus 在同步成功之后调用
+func (s *CachedPublishedArticleStore) Invalidate(id int64) {
+	s.mu.Lock()
+	art, ok := s.invalidateLocked(id)
+	s.mu.Unlock()
+	if ok {
+		s.notify(Event{Type: EventDelete, Article: art})
+	}
+}
+
+// invalidateLocked 要求调用方已经持有 s.mu 的写锁
+func (s *CachedPublishedArticleStore) invalidateLocked(id int64) (domain.Article, bool) {
+	entry, ok := s.byId[id]
+	if !ok {
+		return domain.Article{}, false
+	}
+	delete(s.byId, id)
+	if byAuthor, ok := s.byAuthor[entry.art.Author.Id]; ok {
+		delete(byAuthor, id)
+		if len(byAuthor) == 0 {
+			delete(s.byAuthor, entry.art.Author.Id)
+		}
+	}
+	if entry.art.Slug != "" {
+		delete(s.bySlug, entry.art.Slug)
+	}
+	s.removeFromLRULocked(id)
+	return entry.art, true
+}
+
+// removeFromLRULocked 要求调用方已经持有 s.mu 的写锁。
+// invalidateLocked 和 touch 都要用到它：不把旧的 id 从 lruOrder 里摘掉的话，
+// 反复 Put 同一篇文章（比如频繁地同步/失效/回源）会让 lruOrder 里堆满同一个 id
+// 的陈旧副本，逐渐撑爆内存，evictIfNeededLocked 也会白白在这些空条目上空转。
+func (s *CachedPublishedArticleStore) removeFromLRULocked(id int64) {
+	for i, v := range s.lruOrder {
+		if v == id {
+			s.lruOrder = append(s.lruOrder[:i], s.lruOrder[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *CachedPublishedArticleStore) touch(id int64) {
+	s.mu.Lock()
+	s.removeFromLRULocked(id)
+	s.lruOrder = append(s.lruOrder, id)
+	s.mu.Unlock()
+}
+
+// evictIfNeededLocked 要求调用方已经持有 s.mu 的写锁
+func (s *CachedPublishedArticleStore) evictIfNeededLocked() {
+	for len(s.byId) > s.capacity && len(s.lruOrder) > 0 {
+		oldest := s.lruOrder[0]
+		s.lruOrder = s.lruOrder[1:]
+		if _, ok := s.invalidateLocked(oldest); ok {
+			atomic.AddInt64(&s.metrics.Evictions, 1)
+		}
+	}
+}
+
+// Resync 在启动时把最近发表的 topN 篇文章预热进缓存
+func (s *CachedPublishedArticleStore) Resync(ctx context.Context, topN int) error {
+	entities, err := s.readerDAO.ListRecentPublished(ctx, topN)
+	if err != nil {
+		return err
+	}
+	for _, e := range entities {
+		var tags []string
+		if e.Tags != "" {
+			tags = strings.Split(e.Tags, ",")
+		}
+		// Tags/Slug/Version 都要跟 toDomain 保持一致地映射过来——缺了 Slug，
+		// Put 就不会把这条记录放进 bySlug 索引（Put 只在 art.Slug != "" 时才建这条索引），
+		// 预热进来的文章在下一次被写之前，GetBySlug 永远查不到它们。
+		s.Put(domain.Article{
+			Id:      e.Id,
+			Title:   e.Title,
+			Content: e.Content,
+			Author:  domain.Author{Id: e.AuthorId},
+			Status:  domain.ArticleStatus(e.Status),
+			Tags:    tags,
+			Slug:    e.Slug,
+			Version: e.Version,
+		})
+	}
+	return nil
+}
+
+// Watch 注册一个 channel，之后这份缓存里发生的增/改/删都会往里面发一份事件。
+// channel 满了就丢弃事件而不是阻塞写路径，订阅方自己保证消费得过来。
+func (s *CachedPublishedArticleStore) Watch(ch chan Event) {
+	s.watchersMu.Lock()
+	s.watchers = append(s.watchers, ch)
+	s.watchersMu.Unlock()
+}
+
+func (s *CachedPublishedArticleStore) notify(evt Event) {
+	s.watchersMu.Lock()
+	defer s.watchersMu.Unlock()
+	for _, ch := range s.watchers {
+		select {
+		case ch <- evt:
+		default:
+		}
+	}
+}
+
+func (s *CachedPublishedArticleStore) Metrics() CacheMetrics {
+	return CacheMetrics{
+		Hits:      atomic.LoadInt64(&s.metrics.Hits),
+		Misses:    atomic.LoadInt64(&s.metrics.Misses),
+		Evictions: atomic.LoadInt64(&s.metrics.Evictions),
+	}
+}
@@ -0,0 +1,90 @@
+package article
+
+import (
+	"testing"
+
+	"webook/internal/domain"
+)
+
+func newTestStore(capacity int) *CachedPublishedArticleStore {
+	return NewCachedPublishedArticleStore(nil, capacity)
+}
+
+func TestCachedPublishedArticleStore_PutIndexesAllThreeDimensions(t *testing.T) {
+	s := newTestStore(10)
+	art := domain.Article{
+		Id:     1,
+		Title:  "标题",
+		Author: domain.Author{Id: 100},
+		Slug:   "hello-world",
+	}
+	s.Put(art)
+
+	if _, ok := s.GetById(1); !ok {
+		t.Fatal("expected GetById to hit")
+	}
+	if _, ok := s.GetBySlug("hello-world"); !ok {
+		t.Fatal("expected GetBySlug to hit")
+	}
+	if got := s.GetByAuthor(100); len(got) != 1 {
+		t.Fatalf("expected GetByAuthor to return 1 article, got %d", len(got))
+	}
+}
+
+func TestCachedPublishedArticleStore_PutWithoutSlugSkipsSlugIndex(t *testing.T) {
+	s := newTestStore(10)
+	s.Put(domain.Article{Id: 1, Author: domain.Author{Id: 100}})
+
+	if _, ok := s.GetBySlug(""); ok {
+		t.Fatal("empty slug should never be indexed")
+	}
+}
+
+func TestCachedPublishedArticleStore_InvalidateClearsAllIndices(t *testing.T) {
+	s := newTestStore(10)
+	s.Put(domain.Article{Id: 1, Author: domain.Author{Id: 100}, Slug: "hello"})
+	s.Invalidate(1)
+
+	if _, ok := s.GetById(1); ok {
+		t.Fatal("expected GetById to miss after Invalidate")
+	}
+	if _, ok := s.GetBySlug("hello"); ok {
+		t.Fatal("expected GetBySlug to miss after Invalidate")
+	}
+	if got := s.GetByAuthor(100); len(got) != 0 {
+		t.Fatalf("expected GetByAuthor to return 0 articles after Invalidate, got %d", len(got))
+	}
+}
+
+func TestCachedPublishedArticleStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	s := newTestStore(2)
+	s.Put(domain.Article{Id: 1, Author: domain.Author{Id: 100}})
+	s.Put(domain.Article{Id: 2, Author: domain.Author{Id: 100}})
+	// 访问一下 id=1，让它变成最近使用的，id=2 才是最久未使用的那个
+	s.GetById(1)
+	s.Put(domain.Article{Id: 3, Author: domain.Author{Id: 100}})
+
+	if _, ok := s.GetById(2); ok {
+		t.Fatal("expected id=2 to be evicted as least recently used")
+	}
+	if _, ok := s.GetById(1); !ok {
+		t.Fatal("expected id=1 to survive eviction, it was touched more recently")
+	}
+	if _, ok := s.GetById(3); !ok {
+		t.Fatal("expected id=3 to be present, it was just added")
+	}
+	if metrics := s.Metrics(); metrics.Evictions != 1 {
+		t.Fatalf("expected 1 eviction recorded, got %d", metrics.Evictions)
+	}
+}
+
+func TestCachedPublishedArticleStore_RepeatedPutsOfSameIdDoNotLeakLRUEntries(t *testing.T) {
+	s := newTestStore(10)
+	art := domain.Article{Id: 1, Author: domain.Author{Id: 100}}
+	for i := 0; i < 5; i++ {
+		s.Put(art)
+	}
+	if got := len(s.lruOrder); got != 1 {
+		t.Fatalf("expected lruOrder to dedupe repeated Puts of the same id, got %d entries", got)
+	}
+}
@@ -0,0 +1,143 @@
+package article
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// sortableColumns 是 WithOrderBy 允许排序的列的白名单。orderBy/orderDir 最终会被
+// 直接拼进 base.Order(...) 这个原始 SQL 片段，field/dir 又来自
+// article_search.go 里未经校验的 query 参数，不挡在这里就是一个活的 SQL 注入点。
+var sortableColumns = map[string]bool{
+	"id":    true,
+	"ctime": true,
+	"utime": true,
+	"title": true,
+}
+
+// Query 是 ArticleRepository.Search 的查询条件。
+//
+// List 只支持 uid/offset/limit，每多一种筛选维度就得加一个 ListByXxx，
+// 用 QueryOption 把筛选条件组装成一个 Query，Search 只需要这一个入口，
+// DAO 层只管把 Query 里的条件翻译成 Where/Order，不用关心业务上是怎么拼出来的。
+type Query struct {
+	page, size int
+	orderBy    string
+	orderDir   string
+
+	clauses []func(tx *gorm.DB) *gorm.DB
+}
+
+type QueryOption func(q *Query)
+
+func NewQuery(opts ...QueryOption) Query {
+	q := Query{page: 1, size: 10, orderBy: "id", orderDir: "DESC"}
+	for _, opt := range opts {
+		opt(&q)
+	}
+	return q
+}
+
+// WithAuthor 按作者过滤
+func WithAuthor(id int64) QueryOption {
+	return func(q *Query) {
+		q.clauses = append(q.clauses, func(tx *gorm.DB) *gorm.DB {
+			return tx.Where("author_id = ?", id)
+		})
+	}
+}
+
+// WithStatusIn 按状态过滤，传空就什么都不做
+func WithStatusIn(statuses ...uint8) QueryOption {
+	return func(q *Query) {
+		if len(statuses) == 0 {
+			return
+		}
+		q.clauses = append(q.clauses, func(tx *gorm.DB) *gorm.DB {
+			return tx.Where("status IN ?", statuses)
+		})
+	}
+}
+
+// WithTitleLike 标题模糊匹配
+func WithTitleLike(s string) QueryOption {
+	return func(q *Query) {
+		if s == "" {
+			return
+		}
+		q.clauses = append(q.clauses, func(tx *gorm.DB) *gorm.DB {
+			return tx.Where("title LIKE ?", "%"+s+"%")
+		})
+	}
+}
+
+// WithCreatedBetween 按创建时间区间过滤
+func WithCreatedBetween(start, end time.Time) QueryOption {
+	return func(q *Query) {
+		q.clauses = append(q.clauses, func(tx *gorm.DB) *gorm.DB {
+			return tx.Where("ctime BETWEEN ? AND ?", start.UnixMilli(), end.UnixMilli())
+		})
+	}
+}
+
+// WithTagAny 命中任意一个 tag 就算匹配。
+// tag 目前是逗号拼接存在一个字符串列里，没有专门的标签表，所以这里用 LIKE 兜底；
+// 量级上来之后要换成独立的 article_tags 表再重写这个 option。
+func WithTagAny(tags ...string) QueryOption {
+	return func(q *Query) {
+		if len(tags) == 0 {
+			return
+		}
+		q.clauses = append(q.clauses, func(tx *gorm.DB) *gorm.DB {
+			cond := tx.Session(&gorm.Session{NewDB: true})
+			for i, tag := range tags {
+				if i == 0 {
+					cond = cond.Where("tags LIKE ?", "%"+tag+"%")
+				} else {
+					cond = cond.Or("tags LIKE ?", "%"+tag+"%")
+				}
+			}
+			return tx.Where(cond)
+		})
+	}
+}
+
+// WithOrderBy 指定排序字段和方向，字段/方向留空则沿用默认的 "id DESC"。
+// field 必须命中 sortableColumns 白名单，dir 必须是 ASC/DESC，不满足就忽略、
+// 保留上一次（或默认）的值——不能让调用方拼任意字符串进最终的 Order 子句。
+func WithOrderBy(field, dir string) QueryOption {
+	return func(q *Query) {
+		if sortableColumns[strings.ToLower(field)] {
+			q.orderBy = strings.ToLower(field)
+		}
+		dir = strings.ToUpper(dir)
+		if dir == "ASC" || dir == "DESC" {
+			q.orderDir = dir
+		}
+	}
+}
+
+// WithPage 分页，page 从 1 开始
+func WithPage(page, size int) QueryOption {
+	return func(q *Query) {
+		if page > 0 {
+			q.page = page
+		}
+		if size > 0 {
+			q.size = size
+		}
+	}
+}
+
+func (q Query) apply(tx *gorm.DB) *gorm.DB {
+	for _, clause := range q.clauses {
+		tx = clause(tx)
+	}
+	return tx
+}
+
+func (q Query) offset() int {
+	return (q.page - 1) * q.size
+}
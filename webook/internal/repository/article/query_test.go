@@ -0,0 +1,59 @@
+package article
+
+import "testing"
+
+func TestWithOrderBy_RejectsColumnsOutsideWhitelist(t *testing.T) {
+	// field 和 dir 是各自独立校验的：field 不在白名单里会被拒绝，但不影响
+	// dir 单独生效（dir 本身是合法的 ASC/DESC）。
+	q := NewQuery(WithOrderBy("password", "ASC"))
+	if q.orderBy != "id" {
+		t.Fatalf("expected orderBy to fall back to default 'id', got %q", q.orderBy)
+	}
+	if q.orderDir != "ASC" {
+		t.Fatalf("expected orderDir to still accept a valid 'ASC', got %q", q.orderDir)
+	}
+}
+
+func TestWithOrderBy_AcceptsWhitelistedColumnCaseInsensitively(t *testing.T) {
+	q := NewQuery(WithOrderBy("TITLE", "asc"))
+	if q.orderBy != "title" {
+		t.Fatalf("expected orderBy to be normalized to 'title', got %q", q.orderBy)
+	}
+	if q.orderDir != "ASC" {
+		t.Fatalf("expected orderDir to be normalized to 'ASC', got %q", q.orderDir)
+	}
+}
+
+func TestWithOrderBy_RejectsDirectionOutsideAscDesc(t *testing.T) {
+	q := NewQuery(WithOrderBy("title", "id; DROP TABLE articles"))
+	if q.orderDir != "DESC" {
+		t.Fatalf("expected orderDir to keep default 'DESC' when dir is not ASC/DESC, got %q", q.orderDir)
+	}
+}
+
+func TestWithStatusIn_EmptyArgsAddsNoClause(t *testing.T) {
+	q := NewQuery(WithStatusIn())
+	if len(q.clauses) != 0 {
+		t.Fatalf("expected WithStatusIn() with no statuses to add no clause, got %d clauses", len(q.clauses))
+	}
+}
+
+func TestWithPage_IgnoresNonPositiveValues(t *testing.T) {
+	q := NewQuery(WithPage(0, -1))
+	if q.page != 1 {
+		t.Fatalf("expected page to keep default 1, got %d", q.page)
+	}
+	if q.size != 10 {
+		t.Fatalf("expected size to keep default 10, got %d", q.size)
+	}
+	if got := q.offset(); got != 0 {
+		t.Fatalf("expected offset 0 for page 1, got %d", got)
+	}
+}
+
+func TestWithPage_ComputesOffsetFromPage(t *testing.T) {
+	q := NewQuery(WithPage(3, 20))
+	if got := q.offset(); got != 40 {
+		t.Fatalf("expected offset 40 for page=3 size=20, got %d", got)
+	}
+}
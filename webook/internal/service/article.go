@@ -2,20 +2,39 @@ package service
 
 import (
 	"context"
+	"errors"
+
 	"github.com/gin-gonic/gin"
 	"webook/internal/domain"
+	"webook/internal/domain/article/fsm"
 	"webook/internal/repository/article"
 	"webook/pkg/logger"
 )
 
+// ErrNotArticleOwner 在调用方尝试保存/发表一篇不属于自己的文章时返回。
+var ErrNotArticleOwner = errors.New("article: 不是这篇文章的作者")
+
 type ArticleService interface {
 	Save(ctx context.Context, art domain.Article) (int64, error)
 	Publish(ctx context.Context, art domain.Article) (int64, error)
 	Withdraw(ctx context.Context, uid, id int64) error
+	// Submit 把一篇草稿提交审核
+	Submit(ctx context.Context, uid, id int64) error
+	// Approve 审核通过，把文章发表出去
+	Approve(ctx context.Context, uid, id int64) error
+	// Reject 审核驳回，文章退回草稿
+	Reject(ctx context.Context, uid, id int64) error
 	PublishV1(ctx context.Context, art domain.Article) (int64, error)
 	List(ctx context.Context, uid int64, offset, limit int) ([]domain.Article, error)
 	GetById(ctx context.Context, id int64) (domain.Article, error)
 	GetPublishedById(ctx *gin.Context, id int64) (domain.Article, error)
+	// Republish 把一条同步失败、被判定为死信的记录重新投递给 outbox worker
+	Republish(ctx context.Context, artId int64) error
+	// Search 用 article.Query 承载的动态条件查询文章
+	Search(ctx context.Context, q article.Query) ([]domain.Article, int64, error)
+	// PartialUpdate 对应 PATCH /articles/:id，只更新 patch 里非 nil 的字段，
+	// uid 是发起请求的用户，只能改自己名下的文章
+	PartialUpdate(ctx context.Context, uid, id, expectedVersion int64, patch domain.ArticlePatch) (int64, error)
 }
 
 type articleService struct {
@@ -45,22 +64,104 @@ func NewArticleServiceV1(authorRepo article.ArticleAuthorRepository, readerRepo
 }
 
 func (svc *articleService) Withdraw(ctx context.Context, uid, id int64) error {
+	// 具体的合法性校验（比如还没发表的文章不能下架）交给仓储层的状态机去做，
+	// 这里不再是一句 art.Status = xxx 就完事了。
 	return svc.repo.SyncStatus(ctx, uid, id, domain.ArticleStatusPrivate)
 }
 
+// Submit 对应审核流程的第一步：把一篇草稿提交审核，具体的合法性校验
+// （比如已经在审核中的文章不能重复提交）交给 fsm 去做，uid 是否真的拥有这篇
+// 文章则由 SyncStatus 在查出当前状态之后、触发 fsm 之前校验。
+func (svc *articleService) Submit(ctx context.Context, uid, id int64) error {
+	return svc.repo.SyncStatus(ctx, uid, id, domain.ArticleStatusInReview)
+}
+
+// Approve 审核通过，把文章从 InReview 迁移到 Published。
+func (svc *articleService) Approve(ctx context.Context, uid, id int64) error {
+	// 目标是 Published 时，SyncStatus 会按来源状态换成 publish/republish 事件，
+	// 不先挡住的话 Unpublished/Private 的文章也会被直接发表出去。
+	// 不是自己的文章、或者查不到，都交给 SyncStatus 统一报错，这里不区分。
+	if current, err := svc.repo.GetById(ctx, id); err == nil &&
+		current.Author.Id == uid && current.Status != domain.ArticleStatusInReview {
+		return errors.New("article: 文章不在审核中，不能审核通过")
+	}
+	return svc.repo.SyncStatus(ctx, uid, id, domain.ArticleStatusPublished)
+}
+
+// Reject 审核驳回，把文章从 InReview 退回 Unpublished。
+func (svc *articleService) Reject(ctx context.Context, uid, id int64) error {
+	// 原因同 Approve：不先挡住的话 Published/Private 的文章也会被退回 Unpublished。
+	if current, err := svc.repo.GetById(ctx, id); err == nil &&
+		current.Author.Id == uid && current.Status != domain.ArticleStatusInReview {
+		return errors.New("article: 文章不在审核中，不能驳回")
+	}
+	return svc.repo.SyncStatus(ctx, uid, id, domain.ArticleStatusUnpublished)
+}
+
 func (svc *articleService) Save(ctx context.Context, art domain.Article) (int64, error) {
-	// 设置为未发表
-	art.Status = domain.ArticleStatusUnpublished
+	// 编辑已有文章之前，先查出它真实的持久化状态和归属——不能信任调用方传进来的
+	// art.Status（调用方传进来的 art 通常不带状态，是零值 ArticleStatusUnknown），否则 fsm.Fire 校验的
+	// 就是个假的 from，guard 形同虚设，也没法判断这篇文章到底是不是这个 uid 的。
 	if art.Id > 0 {
-		err := svc.update(ctx, art)
-		return art.Id, err
+		current, err := svc.repo.GetById(ctx, art.Id)
+		if err != nil {
+			return 0, err
+		}
+		if current.Author.Id != art.Author.Id {
+			return 0, ErrNotArticleOwner
+		}
+		art.Status = current.Status
+	}
+	// 用状态机代替直接赋值，这样非法的来源状态会在落库之前就被拒绝
+	from, to, err := fsm.Fire(ctx, &art, fsm.EventSaveDraft)
+	if err != nil {
+		return 0, err
+	}
+	var id int64
+	if art.Id > 0 {
+		id = art.Id
+		err = svc.update(ctx, art)
+	} else {
+		id, err = svc.create(ctx, art)
+	}
+	if err != nil {
+		return 0, err
 	}
-	return svc.create(ctx, art)
+	// art 已经落库了，写审计日志失败不能让调用方以为这次保存没有生效——
+	// 这里只记一条错误日志，照常返回 id。
+	if err := svc.repo.LogTransition(ctx, art.Author.Id, id, from, to, fsm.EventSaveDraft, article.ReasonForEvent(fsm.EventSaveDraft)); err != nil {
+		svc.logger.Error("记录文章状态流转审计日志失败",
+			logger.Field{Key: "art_id", Value: id}, logger.Error(err))
+	}
+	return id, nil
 }
 
 func (svc *articleService) Publish(ctx context.Context, art domain.Article) (int64, error) {
-	art.Status = domain.ArticleStatusPublished
-	return svc.repo.Sync(ctx, art)
+	// 原因同 Save：fsm.Fire 必须拿到真实的持久化状态，而不是调用方手上那份可能
+	// 是零值的 art.Status，同时顺便把归属校验也做掉。
+	if art.Id > 0 {
+		current, err := svc.repo.GetById(ctx, art.Id)
+		if err != nil {
+			return 0, err
+		}
+		if current.Author.Id != art.Author.Id {
+			return 0, ErrNotArticleOwner
+		}
+		art.Status = current.Status
+	}
+	from, to, err := fsm.Fire(ctx, &art, fsm.EventPublish)
+	if err != nil {
+		return 0, err
+	}
+	id, err := svc.repo.Sync(ctx, art)
+	if err != nil {
+		return 0, err
+	}
+	if err := svc.repo.LogTransition(ctx, art.Author.Id, id, from, to, fsm.EventPublish, article.ReasonForEvent(fsm.EventPublish)); err != nil {
+		svc.logger.Error("记录文章状态流转审计日志失败",
+			logger.Field{Key: "art_id", Value: id}, logger.Error(err))
+	}
+	return id, nil
 }
 
 // PublishV1 基于使用两种 repository 的写法
@@ -118,3 +219,15 @@ func (svc *articleService) List(ctx context.Context, uid int64, offset, limit in
 func (svc *articleService) GetById(ctx context.Context, id int64) (domain.Article, error) {
 	return svc.repo.GetById(ctx, id)
 }
+
+func (svc *articleService) Republish(ctx context.Context, artId int64) error {
+	return svc.repo.Republish(ctx, artId)
+}
+
+func (svc *articleService) Search(ctx context.Context, q article.Query) ([]domain.Article, int64, error) {
+	return svc.repo.Search(ctx, q)
+}
+
+func (svc *articleService) PartialUpdate(ctx context.Context, uid, id, expectedVersion int64, patch domain.ArticlePatch) (int64, error) {
+	return svc.repo.PartialUpdate(ctx, uid, id, expectedVersion, patch)
+}
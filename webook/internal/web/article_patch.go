@@ -0,0 +1,139 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"webook/internal/domain"
+	"webook/internal/repository/article"
+	"webook/internal/service"
+)
+
+type articlePatchReq struct {
+	Title   *string   `json:"title"`
+	Content *string   `json:"content"`
+	Tags    *[]string `json:"tags"`
+	Status  *uint8    `json:"status"`
+}
+
+// uidFromContext 取出登录中间件放进 gin.Context 的 uid。这里只负责读，
+// 不负责校验登录态本身——路由层已经挂了登录校验中间件，走到 handler 这里
+// uid 应该总是存在，取不到说明中间件配置有问题，按未登录处理更安全。
+func uidFromContext(ctx *gin.Context) (int64, bool) {
+	val, ok := ctx.Get("uid")
+	if !ok {
+		return 0, false
+	}
+	uid, ok := val.(int64)
+	return uid, ok
+}
+
+// NewArticlePatchHandler 构造 PATCH /articles/:id 的处理函数：
+// 只更新请求体里出现的字段，If-Match 头携带的 version 当作乐观锁使用。
+func NewArticlePatchHandler(svc service.ArticleService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		uid, ok := uidFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "未登录"})
+			return
+		}
+		id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"msg": "id 不合法"})
+			return
+		}
+		version, err := strconv.ParseInt(ctx.GetHeader("If-Match"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusPreconditionRequired, gin.H{"msg": "缺少合法的 If-Match"})
+			return
+		}
+		var req articlePatchReq
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"msg": "参数错误"})
+			return
+		}
+		patch := domain.ArticlePatch{
+			Title:   req.Title,
+			Content: req.Content,
+			Tags:    req.Tags,
+		}
+		if req.Status != nil {
+			status := domain.ArticleStatus(*req.Status)
+			patch.Status = &status
+		}
+		newVersion, err := svc.PartialUpdate(ctx, uid, id, version, patch)
+		switch {
+		case errors.Is(err, article.ErrVersionConflict):
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{"msg": "文章已经被修改过，请刷新之后重试"})
+		case err != nil:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"msg": "系统错误"})
+		default:
+			ctx.Header("ETag", strconv.FormatInt(newVersion, 10))
+			ctx.JSON(http.StatusOK, gin.H{"version": newVersion})
+		}
+	}
+}
+
+type articlePutReq struct {
+	Id      int64    `json:"id"`
+	Title   string   `json:"title" binding:"required"`
+	Content string   `json:"content" binding:"required"`
+	Tags    []string `json:"tags" binding:"required"`
+}
+
+// NewArticlePutHandler 构造 PUT /articles/:id 的处理函数。
+// PUT 要求请求体是一篇完整的文章，并且 path 里的 id 必须和 body 里的一致——
+// 这是 PUT 幂等语义的前提，不一致说明客户端八成发错了请求，直接拒绝比静默纠正更安全。
+//
+// 和 PATCH 一样要求 If-Match 带上 version 做乐观锁：PUT 全量覆盖，如果不校验
+// version，两个客户端并发 PUT 同一篇文章就是纯粹的 last-write-wins，后写的会
+// 静默吞掉先写的内容。这里走 PartialUpdate 而不是 Save，复用同一条版本校验的路径，
+// 而不是另起一套。
+func NewArticlePutHandler(svc service.ArticleService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		uid, ok := uidFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "未登录"})
+			return
+		}
+		pathId, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"msg": "id 不合法"})
+			return
+		}
+		version, err := strconv.ParseInt(ctx.GetHeader("If-Match"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusPreconditionRequired, gin.H{"msg": "缺少合法的 If-Match"})
+			return
+		}
+		var req articlePutReq
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"msg": "参数错误"})
+			return
+		}
+		if req.Id != pathId {
+			ctx.JSON(http.StatusConflict, gin.H{"msg": "path 中的 id 和 body 中的 id 不一致"})
+			return
+		}
+		// Author 不从 body 里读：这是一个账户身份字段，客户端传什么都不能信，
+		// 只能用登录态里认证过的 uid，否则任何人都能把自己的文章保存成别人的。
+		title, content, tags := req.Title, req.Content, req.Tags
+		patch := domain.ArticlePatch{
+			Title:   &title,
+			Content: &content,
+			Tags:    &tags,
+		}
+		newVersion, err := svc.PartialUpdate(ctx, uid, pathId, version, patch)
+		switch {
+		case errors.Is(err, article.ErrVersionConflict):
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{"msg": "文章已经被修改过，请刷新之后重试"})
+		case err != nil:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"msg": "系统错误"})
+		default:
+			ctx.Header("ETag", strconv.FormatInt(newVersion, 10))
+			ctx.Status(http.StatusNoContent)
+		}
+	}
+}
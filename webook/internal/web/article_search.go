@@ -0,0 +1,89 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"webook/internal/domain"
+	"webook/internal/repository/article"
+	"webook/internal/service"
+)
+
+// NewArticleSearchHandler 构造 GET /articles/search 的处理函数：
+// 把查询参数解析成 article.QueryOption，再转给 ArticleService.Search。
+//
+// 必须先登录：author_id/status 都是客户端可控的查询参数，不能直接拼进 Query
+// 就去查库。只有在"查自己"（author_id == uid）的时候才信任客户端传的 status；
+// 查别人的文章、或者压根没传 author_id（等于全库搜索），一律强制只看 Published，绝不能让
+// ?author_id=42 把别人的草稿、待审核、已下架甚至软删除的文章也翻出来。
+func NewArticleSearchHandler(svc service.ArticleService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		uid, ok := uidFromContext(ctx)
+		if !ok {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"msg": "未登录"})
+			return
+		}
+
+		opts := []article.QueryOption{
+			article.WithPage(atoiOr(ctx.Query("page"), 1), atoiOr(ctx.Query("size"), 10)),
+			article.WithOrderBy(ctx.Query("order_by"), ctx.Query("order_dir")),
+		}
+		authorId, hasAuthor := int64(0), false
+		if id, err := strconv.ParseInt(ctx.Query("author_id"), 10, 64); err == nil && id > 0 {
+			authorId, hasAuthor = id, true
+			opts = append(opts, article.WithAuthor(id))
+		}
+		if title := ctx.Query("title"); title != "" {
+			opts = append(opts, article.WithTitleLike(title))
+		}
+		if tags := ctx.Query("tags"); tags != "" {
+			opts = append(opts, article.WithTagAny(strings.Split(tags, ",")...))
+		}
+		// 只有明确在查自己名下的文章时，才采信客户端传的 status；
+		// 查别人的（或者根本没限定作者，等于在全库里搜）一律只返回 Published，
+		// 不管客户端传了什么 status 过来。
+		if hasAuthor && authorId == uid {
+			if statuses := ctx.Query("status"); statuses != "" {
+				opts = append(opts, article.WithStatusIn(parseStatuses(statuses)...))
+			}
+		} else {
+			opts = append(opts, article.WithStatusIn(domain.ArticleStatusPublished.ToUint8()))
+		}
+		startMs, startErr := strconv.ParseInt(ctx.Query("start"), 10, 64)
+		endMs, endErr := strconv.ParseInt(ctx.Query("end"), 10, 64)
+		if startErr == nil && endErr == nil {
+			opts = append(opts, article.WithCreatedBetween(time.UnixMilli(startMs), time.UnixMilli(endMs)))
+		}
+
+		arts, total, err := svc.Search(ctx, article.NewQuery(opts...))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"msg": "系统错误"})
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{"list": arts, "total": total})
+	}
+}
+
+func atoiOr(s string, def int) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+func parseStatuses(s string) []uint8 {
+	parts := strings.Split(s, ",")
+	res := make([]uint8, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			continue
+		}
+		res = append(res, uint8(n))
+	}
+	return res
+}